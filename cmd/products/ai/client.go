@@ -10,8 +10,9 @@ import (
 )
 
 type Client struct {
-	openai     *azopenai.Client
-	deployment string
+	openai              *azopenai.Client
+	deployment          string
+	embeddingDeployment string
 }
 
 func NewClient(ctx context.Context) (*Client, error) {
@@ -35,5 +36,12 @@ func NewClient(ctx context.Context) (*Client, error) {
 	return &Client{
 		openai:     client,
 		deployment: deployment,
+		// Embeddings are optional, semantic search falls back gracefully when unset
+		embeddingDeployment: os.Getenv("AZURE_OPENAI_EMBEDDING_DEPLOYMENT"),
 	}, nil
 }
+
+// HasEmbeddings reports whether an embeddings deployment is configured
+func (c *Client) HasEmbeddings() bool {
+	return c.embeddingDeployment != ""
+}
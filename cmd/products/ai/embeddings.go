@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const embeddingTimeout = 5 * time.Second
+
+// EmbedText returns the embedding vector for text using the configured
+// Azure OpenAI embeddings deployment
+func (c *Client) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	if !c.HasEmbeddings() {
+		return nil, fmt.Errorf("AZURE_OPENAI_EMBEDDING_DEPLOYMENT not configured")
+	}
+
+	ctx, span := tracer.Start(ctx, "ai.embed_text", traceOptWithDeployment(c.embeddingDeployment))
+	defer span.End()
+
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+	defer cancel()
+
+	resp, err := c.openai.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
+		Input:          []string{text},
+		DeploymentName: &c.embeddingDeployment,
+	}, nil)
+	if err != nil {
+		span.RecordError(err)
+		RecordEmbeddingRequest("error")
+		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+	}
+
+	if len(resp.Data) == 0 {
+		err := fmt.Errorf("no embedding returned")
+		span.RecordError(err)
+		RecordEmbeddingRequest("error")
+		return nil, err
+	}
+
+	if resp.Usage != nil {
+		span.SetAttributes(attribute.Int("ai.prompt_tokens", int(fromPtr(resp.Usage.PromptTokens))))
+	}
+
+	RecordEmbeddingRequest("success")
+	RecordEmbeddingLatency(time.Since(start).Seconds())
+
+	return resp.Data[0].Embedding, nil
+}
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thiago4go/dapr-store/cmd/products/events"
+)
+
+// EnrichJob describes a single product description that needs enriching
+type EnrichJob struct {
+	ProductID   string
+	Name        string
+	CurrentDesc string
+}
+
+const (
+	defaultEnricherWorkers  = 4
+	enrichQueueSize         = 256
+	breakerFailureThreshold = 5
+	breakerOpenCooldown     = 30 * time.Second
+)
+
+// breakerState mirrors the classic circuit breaker states
+type breakerState int32
+
+const (
+	breakerClosed breakerState = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+// Enricher runs a bounded pool of workers that generate and cache AI
+// descriptions in the background, fronted by a circuit breaker so a
+// struggling Azure OpenAI deployment can't pile up goroutines
+type Enricher struct {
+	client  *Client
+	cache   *Cache
+	jobs    chan EnrichJob
+	workers int
+
+	inFlight int32
+
+	mu                sync.Mutex
+	state             breakerState
+	consecutiveFails  int
+	openedAt          time.Time
+	halfOpenProbeBusy bool
+
+	publisher events.Publisher
+}
+
+// NewEnricher creates an Enricher with the given number of workers (falling
+// back to a sane default when workers <= 0) and starts its worker pool
+func NewEnricher(client *Client, cache *Cache, workers int) *Enricher {
+	if workers <= 0 {
+		workers = defaultEnricherWorkers
+	}
+
+	e := &Enricher{
+		client:  client,
+		cache:   cache,
+		jobs:    make(chan EnrichJob, enrichQueueSize),
+		workers: workers,
+	}
+
+	for i := 0; i < workers; i++ {
+		go e.worker()
+	}
+
+	return e
+}
+
+// SetPublisher wires a CloudEvents publisher into the enricher so a
+// product.ai_enriched event is announced whenever a description is cached
+func (e *Enricher) SetPublisher(publisher events.Publisher) {
+	e.publisher = publisher
+}
+
+// Enqueue submits a product for background description enrichment,
+// dropping the job if the queue is full so callers never block
+func (e *Enricher) Enqueue(job EnrichJob) bool {
+	select {
+	case e.jobs <- job:
+		RecordQueueDepth(len(e.jobs))
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *Enricher) worker() {
+	for job := range e.jobs {
+		RecordQueueDepth(len(e.jobs))
+		atomic.AddInt32(&e.inFlight, 1)
+		RecordWorkersInFlight(int(atomic.LoadInt32(&e.inFlight)))
+
+		e.process(job)
+
+		atomic.AddInt32(&e.inFlight, -1)
+		RecordWorkersInFlight(int(atomic.LoadInt32(&e.inFlight)))
+	}
+}
+
+func (e *Enricher) process(job EnrichJob) {
+	if !e.allowRequest() {
+		return
+	}
+
+	ctx := context.Background()
+
+	description, err := e.client.GenerateDescription(ctx, job.Name, job.CurrentDesc)
+	if err != nil {
+		log.Printf("### AI enricher failed for %s: %v\n", job.ProductID, err)
+		e.recordFailure()
+		return
+	}
+
+	if err := e.cache.Set(ctx, job.ProductID, description); err != nil {
+		log.Printf("### AI enricher cache write failed for %s: %v\n", job.ProductID, err)
+		e.recordFailure()
+		return
+	}
+
+	e.recordSuccess()
+
+	if e.publisher != nil {
+		data := map[string]string{"productId": job.ProductID, "description": description}
+		if err := e.publisher.Publish(ctx, events.TypeProductAIEnriched, data); err != nil {
+			log.Printf("### AI enricher failed to publish ai_enriched event for %s: %v\n", job.ProductID, err)
+		}
+	}
+}
+
+// allowRequest reports whether the breaker currently permits a call,
+// transitioning open -> half-open once the cooldown has elapsed. Half-open
+// admits exactly one in-flight probe at a time - every other worker is
+// turned away until that probe's outcome closes or re-opens the breaker -
+// so a still-unhealthy deployment isn't hit by the whole pool at once.
+func (e *Enricher) allowRequest() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == breakerOpen {
+		if time.Since(e.openedAt) < breakerOpenCooldown {
+			return false
+		}
+		e.setState(breakerHalfOpen)
+		e.halfOpenProbeBusy = false
+	}
+
+	if e.state == breakerHalfOpen {
+		if e.halfOpenProbeBusy {
+			return false
+		}
+		e.halfOpenProbeBusy = true
+	}
+
+	return true
+}
+
+func (e *Enricher) recordFailure() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.consecutiveFails++
+
+	if e.state == breakerHalfOpen || e.consecutiveFails >= breakerFailureThreshold {
+		e.openedAt = time.Now()
+		e.halfOpenProbeBusy = false
+		e.setState(breakerOpen)
+	}
+}
+
+func (e *Enricher) recordSuccess() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.consecutiveFails = 0
+	e.halfOpenProbeBusy = false
+	e.setState(breakerClosed)
+}
+
+// setState updates the breaker state and reports it via metrics; callers
+// must hold e.mu
+func (e *Enricher) setState(to breakerState) {
+	if e.state == to {
+		return
+	}
+	e.state = to
+	RecordBreakerState(int(to))
+}
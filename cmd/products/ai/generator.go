@@ -7,22 +7,26 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const generationTimeout = 5 * time.Second
 
 func (c *Client) GenerateDescription(ctx context.Context, productName, currentDesc string) (string, error) {
-	if !isPlaceholder(currentDesc) {
+	if !IsPlaceholder(currentDesc) {
 		return currentDesc, nil
 	}
 
+	ctx, span := tracer.Start(ctx, "ai.generate_description", traceOptWithDeployment(c.deployment))
+	defer span.End()
+
 	ctx, cancel := context.WithTimeout(ctx, generationTimeout)
 	defer cancel()
 
 	prompt := fmt.Sprintf("Write a compelling 2-3 sentence product description for: %s", productName)
-	
+
 	systemMsg := "You are a creative product description writer. Write engaging, concise descriptions."
-	
+
 	messages := []azopenai.ChatRequestMessageClassification{
 		&azopenai.ChatRequestSystemMessage{
 			Content: &systemMsg,
@@ -40,17 +44,29 @@ func (c *Client) GenerateDescription(ctx context.Context, productName, currentDe
 	}, nil)
 
 	if err != nil {
+		span.RecordError(err)
 		return "", fmt.Errorf("failed to generate description: %w", err)
 	}
 
 	if len(resp.Choices) == 0 || resp.Choices[0].Message == nil || resp.Choices[0].Message.Content == nil {
-		return "", fmt.Errorf("no description generated")
+		err := fmt.Errorf("no description generated")
+		span.RecordError(err)
+		return "", err
+	}
+
+	if resp.Usage != nil {
+		span.SetAttributes(
+			attribute.Int("ai.prompt_tokens", int(fromPtr(resp.Usage.PromptTokens))),
+			attribute.Int("ai.completion_tokens", int(fromPtr(resp.Usage.CompletionTokens))),
+		)
 	}
 
 	return *resp.Choices[0].Message.Content, nil
 }
 
-func isPlaceholder(desc string) bool {
+// IsPlaceholder reports whether desc looks like unenriched seed data rather
+// than a real product description
+func IsPlaceholder(desc string) bool {
 	if len(desc) < 20 {
 		return true
 	}
@@ -63,3 +79,12 @@ func isPlaceholder(desc string) bool {
 func toPtr[T any](v T) *T {
 	return &v
 }
+
+// fromPtr dereferences p, returning the zero value when p is nil
+func fromPtr[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -36,6 +36,59 @@ var (
 		},
 		[]string{"error_type"},
 	)
+
+	aiEmbeddingRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ai_embedding_requests_total",
+			Help: "Total number of AI embedding requests",
+		},
+		[]string{"status"},
+	)
+
+	aiEmbeddingLatencySeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "ai_embedding_latency_seconds",
+			Help:    "Latency of AI embedding requests in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	aiEmbeddingCosineScore = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "ai_embedding_cosine_score",
+			Help:    "Distribution of cosine similarity scores for semantic search candidates",
+			Buckets: prometheus.LinearBuckets(0, 0.1, 10),
+		},
+	)
+
+	aiEnricherQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ai_enricher_queue_depth",
+			Help: "Number of description enrichment jobs currently queued",
+		},
+	)
+
+	aiEnricherWorkersInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ai_enricher_workers_in_flight",
+			Help: "Number of enrichment worker goroutines currently processing a job",
+		},
+	)
+
+	aiEnricherBreakerState = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ai_enricher_breaker_state",
+			Help: "Circuit breaker state for the AI enricher (0=closed, 1=half-open, 2=open)",
+		},
+	)
+
+	aiDescriptionStreamChunkLatencySeconds = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "ai_description_stream_chunk_latency_seconds",
+			Help:    "Latency between successive chunks of a streamed AI description",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
 )
 
 func RecordRequest(status string) {
@@ -53,3 +106,31 @@ func RecordLatency(seconds float64) {
 func RecordError(errorType string) {
 	aiErrorsTotal.WithLabelValues(errorType).Inc()
 }
+
+func RecordEmbeddingRequest(status string) {
+	aiEmbeddingRequestsTotal.WithLabelValues(status).Inc()
+}
+
+func RecordEmbeddingLatency(seconds float64) {
+	aiEmbeddingLatencySeconds.Observe(seconds)
+}
+
+func RecordCosineScore(score float64) {
+	aiEmbeddingCosineScore.Observe(score)
+}
+
+func RecordQueueDepth(n int) {
+	aiEnricherQueueDepth.Set(float64(n))
+}
+
+func RecordWorkersInFlight(n int) {
+	aiEnricherWorkersInFlight.Set(float64(n))
+}
+
+func RecordBreakerState(state int) {
+	aiEnricherBreakerState.Set(float64(state))
+}
+
+func RecordStreamChunkLatency(seconds float64) {
+	aiDescriptionStreamChunkLatencySeconds.Observe(seconds)
+}
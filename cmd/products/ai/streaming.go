@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+)
+
+// streamIdleTimeout bounds the gap between successive chunks, independent of
+// generationTimeout which bounds the stream as a whole
+const streamIdleTimeout = 10 * time.Second
+
+// readResult carries a single ChatCompletionsStream.Read() call back to the
+// select loop racing it against the idle timer
+type readResult struct {
+	completions azopenai.ChatCompletions
+	err         error
+}
+
+// GenerateDescriptionStream generates a product description the same way
+// GenerateDescription does, but streams it chunk by chunk over the returned
+// channel instead of waiting for the full completion. The channel is closed
+// when generation finishes; any failure is sent on the error channel. The
+// whole call, including the time the caller takes to receive each chunk, is
+// bounded by generationTimeout; separately, streamIdleTimeout bounds each
+// wait for the next chunk from Azure OpenAI.
+func (c *Client) GenerateDescriptionStream(ctx context.Context, productName, currentDesc string) (<-chan string, <-chan error) {
+	chunks := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		if !IsPlaceholder(currentDesc) {
+			chunks <- currentDesc
+			return
+		}
+
+		ctx, span := tracer.Start(ctx, "ai.generate_description_stream", traceOptWithDeployment(c.deployment))
+		defer span.End()
+
+		ctx, cancel := context.WithTimeout(ctx, generationTimeout)
+		defer cancel()
+
+		prompt := fmt.Sprintf("Write a compelling 2-3 sentence product description for: %s", productName)
+		systemMsg := "You are a creative product description writer. Write engaging, concise descriptions."
+
+		messages := []azopenai.ChatRequestMessageClassification{
+			&azopenai.ChatRequestSystemMessage{
+				Content: &systemMsg,
+			},
+			&azopenai.ChatRequestUserMessage{
+				Content: azopenai.NewChatRequestUserMessageContent(prompt),
+			},
+		}
+
+		resp, err := c.openai.GetChatCompletionsStream(ctx, azopenai.ChatCompletionsOptions{
+			Messages:       messages,
+			DeploymentName: &c.deployment,
+			MaxTokens:      toPtr(int32(200)),
+			Temperature:    toPtr(float32(0.7)),
+		}, nil)
+		if err != nil {
+			span.RecordError(err)
+			errs <- fmt.Errorf("failed to start description stream: %w", err)
+			return
+		}
+		defer resp.ChatCompletionsStream.Close()
+
+		lastChunk := time.Now()
+
+		for {
+			// Read() blocks with no built-in per-call deadline, so run it on
+			// its own goroutine and race it against an idle timer; closing
+			// the stream on any exit path (deferred above) unblocks a Read()
+			// left in flight once we give up on it.
+			readDone := make(chan readResult, 1)
+			go func() {
+				completions, err := resp.ChatCompletionsStream.Read()
+				readDone <- readResult{completions: completions, err: err}
+			}()
+
+			var result readResult
+			select {
+			case result = <-readDone:
+			case <-time.After(streamIdleTimeout):
+				err := fmt.Errorf("description stream idle for over %s", streamIdleTimeout)
+				span.RecordError(err)
+				errs <- err
+				return
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+
+			if errors.Is(result.err, io.EOF) {
+				return
+			}
+			if result.err != nil {
+				span.RecordError(result.err)
+				errs <- fmt.Errorf("description stream read failed: %w", result.err)
+				return
+			}
+
+			RecordStreamChunkLatency(time.Since(lastChunk).Seconds())
+			lastChunk = time.Now()
+
+			for _, choice := range result.completions.Choices {
+				if choice.Delta == nil || choice.Delta.Content == nil {
+					continue
+				}
+
+				select {
+				case chunks <- *choice.Delta.Content:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return chunks, errs
+}
@@ -0,0 +1,15 @@
+package ai
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/thiago4go/dapr-store/cmd/products/ai")
+
+// traceOptWithDeployment is a shorthand for starting a span tagged with the
+// Azure OpenAI deployment it's calling
+func traceOptWithDeployment(deployment string) trace.SpanStartOption {
+	return trace.WithAttributes(attribute.String("ai.deployment", deployment))
+}
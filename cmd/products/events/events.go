@@ -0,0 +1,102 @@
+// ----------------------------------------------------------------------------
+// CloudEvents v1.0 publishing for product state changes, sent via Dapr pub/sub
+// ----------------------------------------------------------------------------
+
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	dapr "github.com/dapr/go-sdk/client"
+)
+
+const specVersion = "1.0"
+
+// Event types published for product state changes
+const (
+	TypeProductCreated    = "com.dapr-store.product.created"
+	TypeProductUpdated    = "com.dapr-store.product.updated"
+	TypeProductOffered    = "com.dapr-store.product.offered"
+	TypeProductAIEnriched = "com.dapr-store.product.ai_enriched"
+)
+
+// Envelope is a CloudEvents v1.0 JSON envelope
+type Envelope struct {
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	SpecVersion     string      `json:"specversion"`
+	Type            string      `json:"type"`
+	DataContentType string      `json:"datacontenttype"`
+	Time            string      `json:"time"`
+	Data            interface{} `json:"data"`
+}
+
+// Publisher publishes a CloudEvent of eventType wrapping data to a pub/sub topic
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, data interface{}) error
+}
+
+// daprClient is the subset of the Dapr client used for publishing
+type daprClient interface {
+	PublishEvent(ctx context.Context, pubsubName, topicName string, data interface{}, opts ...dapr.PublishEventOption) error
+}
+
+// DaprPublisher publishes CloudEvents via the Dapr pub/sub building block
+type DaprPublisher struct {
+	client     daprClient
+	pubsubName string
+	topic      string
+	source     string
+}
+
+// NewDaprPublisher creates a Publisher that sends events for source through
+// pubsubName/topic using the given Dapr client
+func NewDaprPublisher(client dapr.Client, pubsubName, topic, source string) *DaprPublisher {
+	return &DaprPublisher{
+		client:     client,
+		pubsubName: pubsubName,
+		topic:      topic,
+		source:     source,
+	}
+}
+
+// Publish wraps data in a CloudEvents envelope of eventType and publishes it.
+// The "rawPayload" metadata tells Dapr to put payload on the wire as-is
+// instead of wrapping it in a CloudEvent of its own - without it, Dapr's
+// default enveloping would nest our envelope inside a second one built by
+// the sidecar, and a subscriber's data field would hold another CloudEvent
+// rather than the product payload.
+func (p *DaprPublisher) Publish(ctx context.Context, eventType string, data interface{}) error {
+	envelope := Envelope{
+		ID:              newEventID(),
+		Source:          p.source,
+		SpecVersion:     specVersion,
+		Type:            eventType,
+		DataContentType: "application/json",
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		Data:            data,
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cloudevent: %w", err)
+	}
+
+	return p.client.PublishEvent(ctx, p.pubsubName, p.topic, payload,
+		dapr.PublishEventWithMetadata(map[string]string{"rawPayload": "true"}),
+	)
+}
+
+// newEventID returns a random hex string suitable for a CloudEvents id
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("evt-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	dapr "github.com/dapr/go-sdk/client"
+)
+
+// fakeDaprClient is an in-memory substitute for the Dapr client that records
+// every PublishEvent call instead of talking to a sidecar
+type fakeDaprClient struct {
+	published []json.RawMessage
+}
+
+func (f *fakeDaprClient) PublishEvent(ctx context.Context, pubsubName, topicName string, data interface{}, opts ...dapr.PublishEventOption) error {
+	payload, ok := data.([]byte)
+	if !ok {
+		return errors.New("expected []byte payload")
+	}
+
+	f.published = append(f.published, json.RawMessage(payload))
+	return nil
+}
+
+type productData struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDaprPublisherPublishSendsSingleCloudEventEnvelope(t *testing.T) {
+	fake := &fakeDaprClient{}
+	pub := &DaprPublisher{client: fake, pubsubName: "pubsub", topic: "products", source: "products"}
+
+	err := pub.Publish(context.Background(), TypeProductCreated, productData{ID: "prd1", Name: "Widget"})
+	if err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	if len(fake.published) != 1 {
+		t.Fatalf("expected 1 published event, got %d", len(fake.published))
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(fake.published[0], &envelope); err != nil {
+		t.Fatalf("failed to decode published envelope: %v", err)
+	}
+
+	if envelope.Type != TypeProductCreated {
+		t.Errorf("expected type %q, got %q", TypeProductCreated, envelope.Type)
+	}
+	if envelope.Source != "products" {
+		t.Errorf("expected source %q, got %q", "products", envelope.Source)
+	}
+	if envelope.SpecVersion != specVersion {
+		t.Errorf("expected specversion %q, got %q", specVersion, envelope.SpecVersion)
+	}
+}
+
+// TestPublishSubscribeRoundTrip publishes a product event through the
+// DaprPublisher and decodes it the way a subscriber does, confirming the
+// envelope's data is the product itself rather than a second CloudEvent -
+// i.e. that Publish does not double-wrap its payload.
+func TestPublishSubscribeRoundTrip(t *testing.T) {
+	fake := &fakeDaprClient{}
+	pub := &DaprPublisher{client: fake, pubsubName: "pubsub", topic: "products", source: "products"}
+
+	want := productData{ID: "prd2", Name: "Gadget"}
+
+	if err := pub.Publish(context.Background(), TypeProductUpdated, want); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	var received Envelope
+	if err := json.Unmarshal(fake.published[0], &received); err != nil {
+		t.Fatalf("subscriber failed to decode envelope: %v", err)
+	}
+
+	data, err := json.Marshal(received.Data)
+	if err != nil {
+		t.Fatalf("failed to re-marshal envelope data: %v", err)
+	}
+
+	var got productData
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("envelope.Data did not decode to the published product: %v", err)
+	}
+
+	if got != want {
+		t.Errorf("got product %+v, want %+v", got, want)
+	}
+}
@@ -5,22 +5,68 @@
 package impl
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/thiago4go/dapr-store/cmd/products/ai"
+	"github.com/thiago4go/dapr-store/cmd/products/events"
 	"github.com/thiago4go/dapr-store/cmd/products/spec"
 	dapr "github.com/dapr/go-sdk/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/thiago4go/dapr-store/cmd/products/impl")
+
+const (
+	// embeddingKeyPrefix is prepended to a product ID to form its embedding state key
+	embeddingKeyPrefix = "emb-"
+
+	// productsIndexKey and productsOnOfferIndexKey hold the maintained lists
+	// of product IDs used when the state store doesn't support the query API
+	productsIndexKey        = "products-index"
+	productsOnOfferIndexKey = "products-onoffer-index"
+)
+
+// errQueryUnsupported signals that the configured state store component
+// doesn't implement the Dapr state Query API, so callers should fall back
+// to the maintained index instead
+var errQueryUnsupported = errors.New("dapr state query API not supported by this component")
+
 // DaprProductService is a Dapr based implementation of ProductService interface
 type DaprProductService struct {
 	serviceName string
 	storeName   string
 	daprPort    string
 	daprClient  dapr.Client
+
+	aiClient   *ai.Client
+	embeddings map[string][]float32
+	embMu      sync.RWMutex
+
+	// queryUnsupported is latched once the query API is found unsupported,
+	// so later calls go straight to the index fallback. It's read and
+	// written from concurrent HTTP handler goroutines, hence the atomic.
+	queryUnsupported atomic.Bool
+
+	publisher events.Publisher
+
+	// stock is an in-memory per-product inventory count, adjusted via the
+	// inventory pub/sub subscription handled in cmd/products/routes.go
+	stock   map[string]int
+	stockMu sync.RWMutex
 }
 
 // NewDaprService creates a new Dapr-based ProductService
@@ -29,12 +75,14 @@ func NewDaprService(serviceName, storeName string) *DaprProductService {
 	if err != nil {
 		panic(fmt.Sprintf("failed to create Dapr client: %v", err))
 	}
-	
+
 	return &DaprProductService{
 		serviceName: serviceName,
 		storeName:   storeName,
 		daprPort:    "3500", // Default Dapr sidecar port
 		daprClient:  client,
+		embeddings:  make(map[string][]float32),
+		stock:       make(map[string]int),
 	}
 }
 
@@ -43,86 +91,418 @@ func (s *DaprProductService) GetDaprClient() dapr.Client {
 	return s.daprClient
 }
 
-// SaveProduct saves a product to Dapr state store
-func (s *DaprProductService) SaveProduct(product spec.Product) error {
-	url := fmt.Sprintf("http://localhost:%s/v1.0/state/%s", s.daprPort, s.storeName)
-	
-	state := []map[string]interface{}{
-		{
-			"key":   product.ID,
-			"value": product,
-		},
+// SetAIClient wires an AI client into the service so semantic search and
+// embedding precomputation can be enabled
+func (s *DaprProductService) SetAIClient(client *ai.Client) {
+	s.aiClient = client
+}
+
+// SetPublisher wires a CloudEvents publisher into the service so product
+// mutations are announced over Dapr pub/sub
+func (s *DaprProductService) SetPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// AdjustStock updates the in-memory stock count for productID by delta and
+// returns the new count; it's the consumer side of the inventory pub/sub
+// topic registered at /dapr/subscribe
+func (s *DaprProductService) AdjustStock(productID string, delta int) int {
+	s.stockMu.Lock()
+	defer s.stockMu.Unlock()
+
+	s.stock[productID] += delta
+	return s.stock[productID]
+}
+
+// MarkInitialized writes the products-initialized sentinel key, kept out of
+// products-index so it never shows up as a catalog entry
+func (s *DaprProductService) MarkInitialized(ctx context.Context) error {
+	return s.saveStateTransaction(ctx, map[string]interface{}{
+		"products-initialized": spec.Product{ID: "products-initialized"},
+	})
+}
+
+// SaveProduct saves a product to the Dapr state store and keeps the
+// products-index / products-onoffer-index up to date in the same transaction,
+// then publishes a CloudEvent announcing the mutation
+func (s *DaprProductService) SaveProduct(ctx context.Context, product spec.Product) error {
+	index, err := s.getIndex(ctx, productsIndexKey)
+	if err != nil {
+		return fmt.Errorf("failed to load products index: %w", err)
 	}
-	
-	data, _ := json.Marshal(state)
-	req, _ := http.NewRequest("POST", url, strings.NewReader(string(data)))
-	req.Header.Set("Content-Type", "application/json")
-	
-	resp, err := http.DefaultClient.Do(req)
+
+	onOfferIndex, err := s.getIndex(ctx, productsOnOfferIndexKey)
 	if err != nil {
+		return fmt.Errorf("failed to load on-offer index: %w", err)
+	}
+
+	if product.OnOffer {
+		onOfferIndex = addToIndex(onOfferIndex, product.ID)
+	} else {
+		onOfferIndex = removeFromIndex(onOfferIndex, product.ID)
+	}
+
+	eventType := events.TypeProductUpdated
+	if _, err := s.getProduct(ctx, product.ID); err != nil {
+		eventType = events.TypeProductCreated
+	} else if product.OnOffer {
+		eventType = events.TypeProductOffered
+	}
+
+	if err := s.saveStateTransaction(ctx, map[string]interface{}{
+		product.ID:              product,
+		productsIndexKey:        addToIndex(index, product.ID),
+		productsOnOfferIndexKey: onOfferIndex,
+	}); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != 204 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to save product: %s", body)
+
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, eventType, product); err != nil {
+			log.Printf("### Warning: failed to publish %s event for %s: %v\n", eventType, product.ID, err)
+		}
 	}
-	
+
 	return nil
 }
 
 // QueryProducts queries products by a specific field
-func (s *DaprProductService) QueryProducts(column, term string) ([]spec.Product, error) {
+func (s *DaprProductService) QueryProducts(ctx context.Context, column, term string) ([]spec.Product, error) {
+	column = strings.ToLower(column)
+
 	// For simple queries, get by key
 	if column == "id" {
-		product, err := s.getProduct(term)
+		product, err := s.getProduct(ctx, term)
 		if err != nil {
 			return nil, err
 		}
 		return []spec.Product{product}, nil
 	}
-	
-	// For other queries, we need to scan all products (limitation of key-value store)
-	all, err := s.AllProducts()
+
+	products, _, err := s.ListProducts(ctx, spec.Filter{Column: column, Values: []string{term}}, spec.Page{})
+	return products, err
+}
+
+// AllProducts returns every product, paging through ListProducts
+func (s *DaprProductService) AllProducts(ctx context.Context) ([]spec.Product, error) {
+	var all []spec.Product
+	page := spec.Page{Limit: 100}
+
+	for {
+		products, token, err := s.ListProducts(ctx, spec.Filter{}, page)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, products...)
+
+		if token == "" {
+			break
+		}
+		page.Token = token
+	}
+
+	return all, nil
+}
+
+// ListProducts returns a page of products matching filter, using the Dapr
+// state Query API when the configured component supports it and falling
+// back to the maintained catalog index otherwise
+func (s *DaprProductService) ListProducts(ctx context.Context, filter spec.Filter, page spec.Page) ([]spec.Product, string, error) {
+	if !s.queryUnsupported.Load() {
+		products, token, err := s.queryState(ctx, filter, page)
+		if err == nil {
+			return products, token, nil
+		}
+		if !errors.Is(err, errQueryUnsupported) {
+			return nil, "", err
+		}
+		s.queryUnsupported.Store(true)
+	}
+
+	return s.listProductsFromIndex(ctx, filter, page)
+}
+
+// stateQueryResult is the response body of the Dapr state Query API
+type stateQueryResult struct {
+	Results []struct {
+		Key  string          `json:"key"`
+		Data json.RawMessage `json:"data"`
+	} `json:"results"`
+	Token string `json:"token"`
+}
+
+// queryState translates filter/page into a Dapr state Query API request
+func (s *DaprProductService) queryState(ctx context.Context, filter spec.Filter, page spec.Page) ([]spec.Product, string, error) {
+	ctx, span := tracer.Start(ctx, "dapr.state.query", trace.WithAttributes(
+		attribute.String("dapr.store_name", s.storeName),
+	))
+	defer span.End()
+
+	body := map[string]interface{}{}
+
+	if filter.Column != "" && len(filter.Values) > 0 {
+		field, values := queryStateField(filter)
+		if len(values) == 1 {
+			body["filter"] = map[string]interface{}{"EQ": map[string]interface{}{field: values[0]}}
+		} else {
+			body["filter"] = map[string]interface{}{"IN": map[string]interface{}{field: values}}
+		}
+	}
+
+	pagination := map[string]interface{}{}
+	if page.Limit > 0 {
+		pagination["limit"] = page.Limit
+	}
+	if page.Token != "" {
+		pagination["token"] = page.Token
+	}
+	if len(pagination) > 0 {
+		body["page"] = pagination
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/v1.0-alpha1/state/%s/query", s.daprPort, s.storeName)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(data)))
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
+	if resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusBadRequest {
+		return nil, "", errQueryUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("query failed: %s", respBody)
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	var result stateQueryResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", err
+	}
+
+	products := make([]spec.Product, 0, len(result.Results))
+	for _, r := range result.Results {
+		if r.Key == "products-initialized" || strings.HasPrefix(r.Key, embeddingKeyPrefix) {
+			continue
+		}
+
+		var p spec.Product
+		if err := json.Unmarshal(r.Data, &p); err != nil {
+			continue
+		}
+		products = append(products, p)
+	}
+
+	return products, result.Token, nil
+}
+
+// queryStateField translates a Filter's internal column/values (which match
+// the maintained-index naming, e.g. "onoffer") into the actual product JSON
+// field name and correctly-typed values the Dapr state Query API expects
+// (the product's OnOffer field serializes as the boolean JSON field "onOffer")
+func queryStateField(filter spec.Filter) (string, []interface{}) {
+	if filter.Column == "onoffer" {
+		values := make([]interface{}, len(filter.Values))
+		for i, v := range filter.Values {
+			values[i] = v == "true" || v == "1"
+		}
+		return "onOffer", values
+	}
+
+	values := make([]interface{}, len(filter.Values))
+	for i, v := range filter.Values {
+		values[i] = v
+	}
+	return filter.Column, values
+}
+
+// listProductsFromIndex serves ListProducts from products-index /
+// products-onoffer-index when the query API isn't available
+func (s *DaprProductService) listProductsFromIndex(ctx context.Context, filter spec.Filter, page spec.Page) ([]spec.Product, string, error) {
+	indexKey := productsIndexKey
+
+	switch filter.Column {
+	case "":
+		// no filter, use the full index
+	case "onoffer":
+		indexKey = productsOnOfferIndexKey
+	default:
+		// the maintained index only supports the unfiltered and on-offer
+		// cases, matching the fields the rest of the app actually queries by
+		return nil, "", nil
+	}
+
+	ids, err := s.getIndex(ctx, indexKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := 0
+	if page.Token != "" {
+		if n, err := strconv.Atoi(page.Token); err == nil {
+			start = n
+		}
+	}
+	if start >= len(ids) {
+		return nil, "", nil
+	}
+
+	end := len(ids)
+	if page.Limit > 0 && start+page.Limit < end {
+		end = start + page.Limit
+	}
+
+	products := make([]spec.Product, 0, end-start)
+	for _, id := range ids[start:end] {
+		p, err := s.getProduct(ctx, id)
+		if err != nil {
+			continue
+		}
+		products = append(products, p)
+	}
+
+	nextToken := ""
+	if end < len(ids) {
+		nextToken = strconv.Itoa(end)
+	}
+
+	return products, nextToken, nil
+}
+
+// getIndex reads a maintained ID index (products-index or
+// products-onoffer-index) from the state store
+func (s *DaprProductService) getIndex(ctx context.Context, key string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "dapr.state.get", trace.WithAttributes(
+		attribute.String("dapr.store_name", s.storeName),
+		attribute.String("dapr.key", key),
+	))
+	defer span.End()
+
+	url := fmt.Sprintf("http://localhost:%s/v1.0/state/%s/%s", s.daprPort, s.storeName, key)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
-	
-	var results []spec.Product
-	for _, p := range all {
-		switch column {
-		case "onOffer":
-			if term == "true" && p.OnOffer {
-				results = append(results, p)
-			}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
+	if resp.StatusCode == 204 || resp.StatusCode == 404 {
+		return nil, nil
+	}
+
+	var ids []string
+	if err := json.NewDecoder(resp.Body).Decode(&ids); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// saveStateTransaction upserts a set of key/value pairs in a single Dapr
+// state transaction
+func (s *DaprProductService) saveStateTransaction(ctx context.Context, kv map[string]interface{}) error {
+	ctx, span := tracer.Start(ctx, "dapr.state.transaction", trace.WithAttributes(
+		attribute.String("dapr.store_name", s.storeName),
+		attribute.Int("dapr.key_count", len(kv)),
+	))
+	defer span.End()
+
+	operations := make([]map[string]interface{}, 0, len(kv))
+	for key, value := range kv {
+		operations = append(operations, map[string]interface{}{
+			"operation": "upsert",
+			"request": map[string]interface{}{
+				"key":   key,
+				"value": value,
+			},
+		})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"operations": operations})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	url := fmt.Sprintf("http://localhost:%s/v1.0/state/%s/transactions", s.daprPort, s.storeName)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(data)))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
+	if resp.StatusCode != 204 {
+		body, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("failed to save transaction: %s", body)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// addToIndex returns index with id appended if not already present
+func addToIndex(index []string, id string) []string {
+	for _, existing := range index {
+		if existing == id {
+			return index
 		}
 	}
-	
-	return results, nil
+	return append(index, id)
 }
 
-// AllProducts returns all products (scans all keys with "prd" prefix)
-func (s *DaprProductService) AllProducts() ([]spec.Product, error) {
-	// This is a simplified implementation
-	// In production, you'd use Dapr query API or maintain an index
-	var products []spec.Product
-	
-	// Try to get products by known IDs (prd1-prd100)
-	for i := 1; i <= 100; i++ {
-		id := fmt.Sprintf("prd%d", i)
-		product, err := s.getProduct(id)
-		if err == nil {
-			products = append(products, product)
+// removeFromIndex returns index with id removed, if present
+func removeFromIndex(index []string, id string) []string {
+	results := make([]string, 0, len(index))
+	for _, existing := range index {
+		if existing != id {
+			results = append(results, existing)
 		}
 	}
-	
-	return products, nil
+	return results
 }
 
 // SearchProducts searches products by name or description
-func (s *DaprProductService) SearchProducts(query string) ([]spec.Product, error) {
-	all, err := s.AllProducts()
+func (s *DaprProductService) SearchProducts(ctx context.Context, query string) ([]spec.Product, error) {
+	all, err := s.AllProducts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -140,24 +520,183 @@ func (s *DaprProductService) SearchProducts(query string) ([]spec.Product, error
 	return results, nil
 }
 
+// LoadEmbeddings bulk-loads every cached "emb-<id>" vector for products in
+// the catalog index into memory, so semantic search still has candidates to
+// rank after a process restart (PrecomputeEmbedding only runs for products
+// loaded fresh from CSV, which is skipped once already initialized)
+func (s *DaprProductService) LoadEmbeddings(ctx context.Context) error {
+	ids, err := s.getIndex(ctx, productsIndexKey)
+	if err != nil {
+		return fmt.Errorf("failed to load products index: %w", err)
+	}
+
+	s.embMu.Lock()
+	defer s.embMu.Unlock()
+
+	for _, id := range ids {
+		item, err := s.daprClient.GetState(ctx, s.storeName, embeddingKeyPrefix+id, nil)
+		if err != nil || len(item.Value) == 0 {
+			continue
+		}
+
+		var vec []float32
+		if err := json.Unmarshal(item.Value, &vec); err != nil {
+			continue
+		}
+
+		s.embeddings[id] = vec
+	}
+
+	return nil
+}
+
+// SearchProductsSemantic ranks products by cosine similarity between the
+// query embedding and cached per-product embeddings, falling back to the
+// existing substring search when no AI client is configured or when no
+// cached vectors are available to rank against
+func (s *DaprProductService) SearchProductsSemantic(ctx context.Context, query string, topK int, minScore float32) ([]spec.Product, error) {
+	if s.aiClient == nil || !s.aiClient.HasEmbeddings() {
+		return s.SearchProducts(ctx, query)
+	}
+
+	queryVec, err := s.aiClient.EmbedText(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed search query: %w", err)
+	}
+
+	all, err := s.AllProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type scoredProduct struct {
+		product spec.Product
+		score   float32
+	}
+
+	var candidates []scoredProduct
+	ranked := 0
+
+	s.embMu.RLock()
+	for _, p := range all {
+		vec, ok := s.embeddings[p.ID]
+		if !ok {
+			continue
+		}
+		ranked++
+
+		score := cosineSimilarity(queryVec, vec)
+		ai.RecordCosineScore(float64(score))
+
+		if score >= minScore {
+			candidates = append(candidates, scoredProduct{p, score})
+		}
+	}
+	s.embMu.RUnlock()
+
+	if ranked == 0 {
+		return s.SearchProducts(ctx, query)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	if topK > 0 && len(candidates) > topK {
+		candidates = candidates[:topK]
+	}
+
+	results := make([]spec.Product, len(candidates))
+	for i, c := range candidates {
+		results[i] = c.product
+	}
+
+	return results, nil
+}
+
+// PrecomputeEmbedding generates an embedding for a product (name + description),
+// persists it to the state store as "emb-<id>" and caches it in memory
+func (s *DaprProductService) PrecomputeEmbedding(ctx context.Context, product spec.Product) error {
+	if s.aiClient == nil || !s.aiClient.HasEmbeddings() {
+		return nil
+	}
+
+	vec, err := s.aiClient.EmbedText(ctx, product.Name+" "+product.Description)
+	if err != nil {
+		return fmt.Errorf("failed to embed product %s: %w", product.ID, err)
+	}
+
+	data, err := json.Marshal(vec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding for %s: %w", product.ID, err)
+	}
+
+	if err := s.daprClient.SaveState(ctx, s.storeName, embeddingKeyPrefix+product.ID, data, nil); err != nil {
+		return fmt.Errorf("failed to save embedding for %s: %w", product.ID, err)
+	}
+
+	s.embMu.Lock()
+	s.embeddings[product.ID] = vec
+	s.embMu.Unlock()
+
+	return nil
+}
+
+// cosineSimilarity returns the cosine similarity between two equal-length vectors
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float32
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (float32(math.Sqrt(float64(normA))) * float32(math.Sqrt(float64(normB))))
+}
+
 // getProduct retrieves a single product by ID
-func (s *DaprProductService) getProduct(id string) (spec.Product, error) {
+func (s *DaprProductService) getProduct(ctx context.Context, id string) (spec.Product, error) {
+	ctx, span := tracer.Start(ctx, "dapr.state.get", trace.WithAttributes(
+		attribute.String("dapr.store_name", s.storeName),
+		attribute.String("dapr.key", id),
+	))
+	defer span.End()
+
 	url := fmt.Sprintf("http://localhost:%s/v1.0/state/%s/%s", s.daprPort, s.storeName, id)
-	
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		span.RecordError(err)
+		return spec.Product{}, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
+		span.RecordError(err)
 		return spec.Product{}, err
 	}
 	defer resp.Body.Close()
-	
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
 	if resp.StatusCode == 204 || resp.StatusCode == 404 {
-		return spec.Product{}, fmt.Errorf("product not found")
+		err := fmt.Errorf("product not found")
+		span.RecordError(err)
+		return spec.Product{}, err
 	}
-	
+
 	var product spec.Product
 	if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+		span.RecordError(err)
 		return spec.Product{}, err
 	}
-	
+
 	return product, nil
 }
@@ -11,6 +11,7 @@ import (
 	"bufio"
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"strconv"
@@ -18,14 +19,18 @@ import (
 	"time"
 
 	"github.com/thiago4go/dapr-store/cmd/products/ai"
+	"github.com/thiago4go/dapr-store/cmd/products/events"
 	"github.com/thiago4go/dapr-store/cmd/products/impl"
 	"github.com/thiago4go/dapr-store/cmd/products/spec"
+	"github.com/thiago4go/dapr-store/internal/telemetry"
 
 	"github.com/benc-uk/go-rest-api/pkg/api"
 	"github.com/benc-uk/go-rest-api/pkg/env"
 	"github.com/benc-uk/go-rest-api/pkg/logging"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 
 	_ "github.com/joho/godotenv/autoload" // Autoloads .env file if it exists
 	_ "github.com/mattn/go-sqlite3"
@@ -34,9 +39,11 @@ import (
 // API type is a wrap of the common base API with local implementation
 type API struct {
 	*api.Base
-	service  spec.ProductService
-	aiClient *ai.Client
-	aiCache  *ai.Cache
+	service    spec.ProductService
+	aiClient   *ai.Client
+	aiCache    *ai.Cache
+	enricher   *ai.Enricher
+	pubsubName string
 }
 
 var (
@@ -59,13 +66,63 @@ func main() {
 
 	// Check if we should use Dapr state store
 	daprStoreName := env.GetEnvString("DAPR_STORE_NAME", "")
-	
+
+	ctx := context.Background()
+
+	// Wire up OpenTelemetry tracing; a no-op if OTEL_EXPORTER_OTLP_ENDPOINT isn't set
+	shutdownTelemetry, err := telemetry.Init(ctx, serviceName)
+	if err != nil {
+		log.Printf("### Warning: Failed to initialize telemetry: %v\n", err)
+	} else {
+		defer func() {
+			if err := shutdownTelemetry(context.Background()); err != nil {
+				log.Printf("### Warning: Failed to shut down telemetry: %v\n", err)
+			}
+		}()
+	}
+
+	// Initialize AI client if Azure OpenAI is configured, before the service
+	// so product embeddings can be precomputed during initializeProducts
+	var aiClient *ai.Client
+	if os.Getenv("AZURE_OPENAI_ENDPOINT") != "" {
+		log.Println("### Initializing Azure OpenAI client...")
+		client, err := ai.NewClient(ctx)
+		if err != nil {
+			log.Printf("### Warning: Failed to initialize AI client: %v\n", err)
+		} else {
+			aiClient = client
+		}
+	}
+
+	enricherWorkers := env.GetEnvInt("AI_ENRICHER_WORKERS", 0)
+	pubsubName := env.GetEnvString("DAPR_PUBSUB_NAME", "pubsub")
+	productsTopic := env.GetEnvString("PRODUCTS_TOPIC", "products")
+
 	var service spec.ProductService
+	var aiCache *ai.Cache
+	var enricher *ai.Enricher
+
 	if daprStoreName != "" {
 		log.Printf("### Using Dapr state store: %s\n", daprStoreName)
-		service = impl.NewDaprService(serviceName, daprStoreName)
+		daprSvc := impl.NewDaprService(serviceName, daprStoreName)
+
+		publisher := events.NewDaprPublisher(daprSvc.GetDaprClient(), pubsubName, productsTopic, serviceName)
+		daprSvc.SetPublisher(publisher)
+		log.Printf("### Publishing product events via pubsub %q, topic %q\n", pubsubName, productsTopic)
+
+		if aiClient != nil {
+			daprSvc.SetAIClient(aiClient)
+			if err := daprSvc.LoadEmbeddings(ctx); err != nil {
+				log.Printf("### Warning: Failed to load cached embeddings: %v\n", err)
+			}
+			aiCache = ai.NewCache(daprSvc.GetDaprClient())
+			enricher = ai.NewEnricher(aiClient, aiCache, enricherWorkers)
+			enricher.SetPublisher(publisher)
+			log.Println("### AI client, cache and enricher initialized (Dapr)")
+		}
+		service = daprSvc
 		// Initialize products from CSV if needed
-		if err := initializeProducts(service); err != nil {
+		if err := initializeProducts(ctx, service, enricher); err != nil {
 			log.Fatalf("### Failed to initialize products: %v\n", err)
 		}
 	} else {
@@ -75,39 +132,46 @@ func main() {
 			dbFilePath = os.Args[1]
 		}
 		service = impl.NewService(serviceName, dbFilePath)
+		if aiClient != nil {
+			aiCache = ai.NewMemoryCache()
+			enricher = ai.NewEnricher(aiClient, aiCache, enricherWorkers)
+			log.Println("### AI client, cache and enricher initialized (in-memory)")
+		}
 	}
 
 	// Wrapper API with anonymous inner new Base API
 	api := API{
-		Base:    api.NewBase(serviceName, version, buildInfo, healthy),
-		service: service,
-	}
-
-	// Initialize AI client if Azure OpenAI is configured
-	ctx := context.Background()
-	if os.Getenv("AZURE_OPENAI_ENDPOINT") != "" {
-		log.Println("### Initializing Azure OpenAI client...")
-		aiClient, err := ai.NewClient(ctx)
-		if err != nil {
-			log.Printf("### Warning: Failed to initialize AI client: %v\n", err)
-		} else {
-			api.aiClient = aiClient
-			// Initialize cache if using Dapr
-			if daprSvc, ok := service.(*impl.DaprProductService); ok {
-				api.aiCache = ai.NewCache(daprSvc.GetDaprClient())
-				log.Println("### AI client and cache initialized (Dapr)")
-			} else {
-				// Use in-memory cache for SQLite mode
-				api.aiCache = ai.NewMemoryCache()
-				log.Println("### AI client and cache initialized (in-memory)")
-			}
-		}
+		Base:       api.NewBase(serviceName, version, buildInfo, healthy),
+		service:    service,
+		aiClient:   aiClient,
+		aiCache:    aiCache,
+		enricher:   enricher,
+		pubsubName: pubsubName,
 	}
 
 	// Some basic middleware
 	router.Use(middleware.RealIP)
 	router.Use(logging.NewFilteredRequestLogger(regexp.MustCompile(`(^/metrics)|(^/health)`)))
 	router.Use(middleware.Recoverer)
+	// Trace every request with a server span named after its chi route pattern
+	router.Use(otelhttp.NewMiddleware(serviceName,
+		otelhttp.WithSpanNameFormatter(func(operation string, req *http.Request) string {
+			if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				return req.Method + " " + rctx.RoutePattern()
+			}
+			return operation
+		}),
+	))
+	// chi only resolves the route pattern while routing, after the span above
+	// has started, so rename the span once the request has been served
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				trace.SpanFromContext(r.Context()).SetName(r.Method + " " + rctx.RoutePattern())
+			}
+		})
+	})
 	// Some custom middleware for CORS
 	router.Use(api.SimpleCORSMiddleware)
 	// Add Prometheus metrics endpoint, must be before the other routes
@@ -125,10 +189,11 @@ func main() {
 	api.StartServer(serverPort, router, 5*time.Second)
 }
 
-// initializeProducts loads products from CSV if not already initialized
-func initializeProducts(service spec.ProductService) error {
+// initializeProducts loads products from CSV if not already initialized,
+// kicking off background enrichment for any placeholder descriptions
+func initializeProducts(ctx context.Context, service spec.ProductService, enricher *ai.Enricher) error {
 	// Check if already initialized
-	if _, err := service.QueryProducts("id", "products-initialized"); err == nil {
+	if _, err := service.QueryProducts(ctx, "id", "products-initialized"); err == nil {
 		log.Println("### Products already initialized")
 		return nil
 	}
@@ -169,16 +234,22 @@ func initializeProducts(service spec.ProductService) error {
 		
 		// Save via Dapr (this will work with the interface)
 		if daprSvc, ok := service.(*impl.DaprProductService); ok {
-			if err := daprSvc.SaveProduct(product); err != nil {
+			if err := daprSvc.SaveProduct(ctx, product); err != nil {
 				log.Printf("### Warning: Failed to save product %s: %v\n", product.ID, err)
 			}
+			if err := daprSvc.PrecomputeEmbedding(ctx, product); err != nil {
+				log.Printf("### Warning: Failed to precompute embedding for %s: %v\n", product.ID, err)
+			}
+			if enricher != nil && ai.IsPlaceholder(product.Description) {
+				enricher.Enqueue(ai.EnrichJob{ProductID: product.ID, Name: product.Name, CurrentDesc: product.Description})
+			}
 			count++
 		}
 	}
-	
+
 	// Mark as initialized
 	if daprSvc, ok := service.(*impl.DaprProductService); ok {
-		daprSvc.SaveProduct(spec.Product{ID: "products-initialized"})
+		daprSvc.MarkInitialized(ctx)
 	}
 	
 	log.Printf("### Loaded %d products from CSV\n", count)
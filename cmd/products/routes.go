@@ -8,28 +8,46 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/thiago4go/dapr-store/cmd/products/ai"
+	"github.com/thiago4go/dapr-store/cmd/products/events"
+	"github.com/thiago4go/dapr-store/cmd/products/impl"
+	"github.com/thiago4go/dapr-store/cmd/products/spec"
+	"github.com/thiago4go/dapr-store/internal/telemetry"
 	"github.com/benc-uk/go-rest-api/pkg/problem"
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultSearchTopK     = 10
+	defaultSearchMinScore = 0.75
 )
 
 // All routes we need should be registered here
 func (api API) addRoutes(router chi.Router) {
 	router.Get("/get/{id}", api.getProduct)
+	router.Get("/get/{id}/description/stream", api.streamDescription)
 	router.Get("/catalog", api.getCatalog)
 	router.Get("/offers", api.getOffers)
 	router.Get("/search/{query}", api.searchProducts)
+	router.Post("/admin/enrich", api.enrichCatalog)
+	router.Get("/dapr/subscribe", api.daprSubscribe)
+	router.Post("/events/inventory", api.handleInventoryEvent)
 }
 
 // Return a single product
 func (api API) getProduct(resp http.ResponseWriter, req *http.Request) {
 	id := chi.URLParam(req, "id")
 
-	products, err := api.service.QueryProducts("ID", id)
+	products, err := api.service.QueryProducts(req.Context(), "ID", id)
 	if err != nil {
 		problem.Wrap(500, req.RequestURI, id, err).Send(resp)
 		return
@@ -42,50 +60,83 @@ func (api API) getProduct(resp http.ResponseWriter, req *http.Request) {
 	}
 
 	product := products[0]
-	
-	// Enhance with AI description if available
-	if api.aiClient != nil && api.aiCache != nil {
-		product.Description = api.enhanceDescription(req.Context(), product.ID, product.Name, product.Description)
+
+	// Serve the cached AI description if available, otherwise enqueue
+	// background enrichment and fall back to the stored description
+	if api.aiCache != nil {
+		product.Description = api.enrichedDescription(req.Context(), product)
 	}
 
 	api.ReturnJSON(resp, product)
 }
 
-// Return the product catalog
+// Return a page of the product catalog, honoring ?limit= and ?page=
 func (api API) getCatalog(resp http.ResponseWriter, req *http.Request) {
-	products, err := api.service.AllProducts()
+	products, nextPage, err := api.service.ListProducts(req.Context(), spec.Filter{}, parsePage(req))
 	if err != nil {
 		problem.Wrap(500, req.RequestURI, "catalog", err).Send(resp)
 		return
 	}
 
-	// Enhance with AI descriptions if available
-	if api.aiClient != nil && api.aiCache != nil {
+	// Serve cached AI descriptions and enqueue enrichment for the rest
+	if api.aiCache != nil {
 		for i := range products {
-			products[i].Description = api.enhanceDescription(req.Context(), products[i].ID, products[i].Name, products[i].Description)
+			products[i].Description = api.enrichedDescription(req.Context(), products[i])
 		}
 	}
 
+	resp.Header().Set("X-Next-Page", nextPage)
 	api.ReturnJSON(resp, products)
 }
 
-// Return the products on offer
+// Return a page of the products on offer, honoring ?limit= and ?page=
 func (api API) getOffers(resp http.ResponseWriter, req *http.Request) {
-	products, err := api.service.QueryProducts("onoffer", "1")
+	filter := spec.Filter{Column: "onoffer", Values: []string{"true"}}
+
+	products, nextPage, err := api.service.ListProducts(req.Context(), filter, parsePage(req))
 	if err != nil {
 		problem.Wrap(500, req.RequestURI, "offers", err).Send(resp)
-
 		return
 	}
 
+	resp.Header().Set("X-Next-Page", nextPage)
 	api.ReturnJSON(resp, products)
 }
 
-// Search the products table
+// parsePage reads the ?limit= and ?page= query params into a spec.Page
+func parsePage(req *http.Request) spec.Page {
+	page := spec.Page{}
+
+	if v := req.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			page.Limit = n
+		}
+	}
+
+	page.Token = req.URL.Query().Get("page")
+
+	return page
+}
+
+// Search the products table, ranking by embedding similarity when available
 func (api API) searchProducts(resp http.ResponseWriter, req *http.Request) {
 	query := chi.URLParam(req, "query")
 
-	products, err := api.service.SearchProducts(query)
+	topK := defaultSearchTopK
+	if v := req.URL.Query().Get("topk"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			topK = n
+		}
+	}
+
+	minScore := float32(defaultSearchMinScore)
+	if v := req.URL.Query().Get("minscore"); v != "" {
+		if f, err := strconv.ParseFloat(v, 32); err == nil {
+			minScore = float32(f)
+		}
+	}
+
+	products, err := api.service.SearchProductsSemantic(req.Context(), query, topK, minScore)
 	if err != nil {
 		problem.Wrap(500, req.RequestURI, query, err).Send(resp)
 		return
@@ -94,32 +145,200 @@ func (api API) searchProducts(resp http.ResponseWriter, req *http.Request) {
 	api.ReturnJSON(resp, products)
 }
 
-// enhanceDescription generates AI description with caching and metrics
-func (api API) enhanceDescription(ctx context.Context, productID, productName, currentDesc string) string {
-	start := time.Now()
-	
-	// Check cache first
-	cached, err := api.aiCache.Get(ctx, productID)
+// streamDescription writes the AI-generated description for a product as
+// Server-Sent Events, one "data:" frame per chunk, ending with "event: done"
+func (api API) streamDescription(resp http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+
+	products, err := api.service.QueryProducts(req.Context(), "ID", id)
+	if err != nil || len(products) < 1 {
+		problem.Wrap(404, req.RequestURI, id, errors.New("product not found")).Send(resp)
+		return
+	}
+	product := products[0]
+
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		problem.Wrap(500, req.RequestURI, id, errors.New("streaming not supported")).Send(resp)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+
+	ctx := req.Context()
+
+	if api.aiClient == nil {
+		writeSSEData(resp, product.Description)
+		fmt.Fprint(resp, "event: done\ndata: {}\n\n")
+		flusher.Flush()
+		return
+	}
+
+	chunks, errs := api.aiClient.GenerateDescriptionStream(ctx, product.Name, product.Description)
+
+	var full strings.Builder
+	streamFailed := false
+
+	for chunks != nil || errs != nil {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				chunks = nil
+				continue
+			}
+			full.WriteString(chunk)
+			writeSSEData(resp, chunk)
+			flusher.Flush()
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				ai.RecordError("stream_failed")
+				streamFailed = true
+			}
+
+		case <-ctx.Done():
+			// Client disconnected; the generation goroutine tears itself
+			// down via the same context, so there's nothing left to do
+			return
+		}
+	}
+
+	// A failed stream leaves full with only a partial description - never
+	// cache it (every later /get/{id} and /catalog would serve the
+	// truncation as if it were the finished description) and tell the
+	// client the stream didn't complete instead of reporting success.
+	if streamFailed {
+		fmt.Fprint(resp, "event: error\ndata: {}\n\n")
+		flusher.Flush()
+		return
+	}
+
+	if api.aiCache != nil && full.Len() > 0 {
+		if err := api.aiCache.Set(ctx, product.ID, full.String()); err != nil {
+			ai.RecordError("cache_failed")
+		}
+	}
+
+	fmt.Fprint(resp, "event: done\ndata: {}\n\n")
+	flusher.Flush()
+}
+
+// writeSSEData writes text as one or more "data:" lines per the SSE spec, so
+// a chunk containing a literal newline can't be mistaken for a frame boundary
+func writeSSEData(w http.ResponseWriter, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// enrichedDescription serves the cached AI description for product if one
+// has already been generated, otherwise enqueues background enrichment (when
+// the current description looks like a placeholder) and returns immediately
+// with whatever description is already stored - it never blocks on OpenAI
+func (api API) enrichedDescription(ctx context.Context, product spec.Product) string {
+	cached, err := api.aiCache.Get(ctx, product.ID)
 	if err == nil && cached != "" {
 		ai.RecordCacheHit()
+		telemetry.LogEvent(ctx, "ai description cache hit", attribute.String("product.id", product.ID))
 		return cached
 	}
-	
-	// Generate new description
-	description, err := api.aiClient.GenerateDescription(ctx, productName, currentDesc)
+	telemetry.LogEvent(ctx, "ai description cache miss", attribute.String("product.id", product.ID))
+
+	if api.enricher != nil && ai.IsPlaceholder(product.Description) {
+		api.enricher.Enqueue(ai.EnrichJob{ProductID: product.ID, Name: product.Name, CurrentDesc: product.Description})
+	}
+
+	return product.Description
+}
+
+// enrichCatalog re-enqueues every catalog product whose description is still a
+// placeholder for background AI description enrichment, returning the number
+// of products queued; products with a real description are skipped
+func (api API) enrichCatalog(resp http.ResponseWriter, req *http.Request) {
+	if api.enricher == nil {
+		problem.Wrap(503, req.RequestURI, "enrich", errors.New("AI enrichment is not configured")).Send(resp)
+		return
+	}
+
+	products, err := api.service.AllProducts(req.Context())
+	if err != nil {
+		problem.Wrap(500, req.RequestURI, "enrich", err).Send(resp)
+		return
+	}
+
+	queued := 0
+	for _, product := range products {
+		if !ai.IsPlaceholder(product.Description) {
+			continue
+		}
+		if api.enricher.Enqueue(ai.EnrichJob{ProductID: product.ID, Name: product.Name, CurrentDesc: product.Description}) {
+			queued++
+		}
+	}
+
+	api.ReturnJSON(resp, map[string]int{"queued": queued})
+}
+
+// subscription describes a single Dapr pub/sub route, per the
+// programmatic subscription contract served at /dapr/subscribe
+type subscription struct {
+	PubsubName string `json:"pubsubname"`
+	Topic      string `json:"topic"`
+	Route      string `json:"route"`
+}
+
+// daprSubscribe tells the Dapr sidecar which topics this service consumes
+func (api API) daprSubscribe(resp http.ResponseWriter, req *http.Request) {
+	api.ReturnJSON(resp, []subscription{
+		{PubsubName: api.pubsubName, Topic: "inventory", Route: "/events/inventory"},
+	})
+}
+
+// inventoryEvent is the payload carried by CloudEvents on the inventory topic
+type inventoryEvent struct {
+	ProductID string `json:"productId"`
+	Delta     int    `json:"delta"`
+}
+
+// handleInventoryEvent consumes the inventory topic and adjusts the
+// in-memory stock count for the product it names
+func (api API) handleInventoryEvent(resp http.ResponseWriter, req *http.Request) {
+	var envelope events.Envelope
+	if err := json.NewDecoder(req.Body).Decode(&envelope); err != nil {
+		problem.Wrap(400, req.RequestURI, "inventory", err).Send(resp)
+		return
+	}
+
+	data, err := json.Marshal(envelope.Data)
 	if err != nil {
-		ai.RecordError("generation_failed")
-		ai.RecordRequest("error")
-		return currentDesc // Graceful fallback
-	}
-	
-	// Cache the result
-	if err := api.aiCache.Set(ctx, productID, description); err != nil {
-		ai.RecordError("cache_failed")
-	}
-	
-	ai.RecordRequest("success")
-	ai.RecordLatency(time.Since(start).Seconds())
-	
-	return description
+		problem.Wrap(400, req.RequestURI, "inventory", err).Send(resp)
+		return
+	}
+
+	var event inventoryEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		problem.Wrap(400, req.RequestURI, "inventory", err).Send(resp)
+		return
+	}
+
+	daprSvc, ok := api.service.(*impl.DaprProductService)
+	if !ok {
+		resp.WriteHeader(http.StatusOK)
+		return
+	}
+
+	stock := daprSvc.AdjustStock(event.ProductID, event.Delta)
+	telemetry.LogEvent(req.Context(), "inventory event applied",
+		attribute.String("product.id", event.ProductID),
+		attribute.Int("stock", stock),
+	)
+
+	resp.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,49 @@
+// ----------------------------------------------------------------------------
+// Shared types for the products service, implemented by each storage backend
+// ----------------------------------------------------------------------------
+
+package spec
+
+import "context"
+
+// Product represents a single item in the store catalog
+type Product struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Cost        float32 `json:"cost"`
+	Description string  `json:"description"`
+	Image       string  `json:"image"`
+	OnOffer     bool    `json:"onOffer"`
+}
+
+// Filter selects products whose column matches one of values (EQ for a
+// single value, IN for several). A zero-value Filter matches everything.
+type Filter struct {
+	Column string
+	Values []string
+}
+
+// Page requests a bounded slice of results, continuing from Token when set.
+// A zero-value Page requests a single backend-default-sized page from the start.
+type Page struct {
+	Limit int
+	Token string
+}
+
+// ProductService is the interface implemented by all product storage backends
+type ProductService interface {
+	SaveProduct(ctx context.Context, product Product) error
+	QueryProducts(ctx context.Context, column, term string) ([]Product, error)
+	AllProducts(ctx context.Context) ([]Product, error)
+	SearchProducts(ctx context.Context, query string) ([]Product, error)
+
+	// SearchProductsSemantic ranks products by similarity between an
+	// embedding of query and cached per-product embeddings, returning at
+	// most topK results with a similarity score of at least minScore.
+	// Backends without embedding support should fall back to SearchProducts.
+	SearchProductsSemantic(ctx context.Context, query string, topK int, minScore float32) ([]Product, error)
+
+	// ListProducts returns a page of products matching filter plus a
+	// continuation token for the next page, empty when there is none.
+	ListProducts(ctx context.Context, filter Filter, page Page) ([]Product, string, error)
+}
@@ -0,0 +1,22 @@
+package telemetry
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogEvent logs msg and, if ctx carries a recording span, adds it as a span
+// event with the same attributes. Use this instead of a bare log call
+// wherever the line is also worth seeing in a trace (cache hits/misses,
+// circuit breaker trips, and the like).
+func LogEvent(ctx context.Context, msg string, attrs ...attribute.KeyValue) {
+	log.Println(msg)
+
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.AddEvent(msg, trace.WithAttributes(attrs...))
+	}
+}
@@ -0,0 +1,100 @@
+// ----------------------------------------------------------------------------
+// OpenTelemetry tracer provider setup shared across dapr-store services
+// ----------------------------------------------------------------------------
+
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Shutdown flushes and stops the tracer provider, call it on process exit
+type Shutdown func(context.Context) error
+
+// Init configures a global OTLP tracer provider for serviceName and installs
+// a W3C tracecontext propagator so traces stitch together with the Dapr
+// sidecar's own spans. The protocol is selected via OTEL_EXPORTER_OTLP_PROTOCOL
+// ("grpc" or "http/protobuf"), the same way the Dapr runtime picks its own.
+// Tracing is opt-in: when OTEL_EXPORTER_OTLP_ENDPOINT isn't set, Init returns
+// a no-op Shutdown.
+func Init(ctx context.Context, serviceName string) (Shutdown, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceNameKey.String(serviceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
+
+// newExporter builds an OTLP trace exporter for either gRPC or HTTP,
+// defaulting to gRPC to match the Dapr runtime's default
+func newExporter(ctx context.Context, endpoint string) (*otlptrace.Exporter, error) {
+	headers := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+
+	switch os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL") {
+	case "http/protobuf", "http":
+		return otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithHeaders(headers),
+			otlptracehttp.WithInsecure(),
+		)
+	default:
+		return otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithHeaders(headers),
+			otlptracegrpc.WithInsecure(),
+		)
+	}
+}
+
+// parseHeaders parses a comma separated key=value list, as used by
+// OTEL_EXPORTER_OTLP_HEADERS
+func parseHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	if raw == "" {
+		return headers
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return headers
+}